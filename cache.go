@@ -0,0 +1,351 @@
+package shardmap
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Reason describes why an entry left a Cache, passed to the callback
+// registered with OnEvict.
+type Reason int
+
+const (
+	// ReasonEvicted means the entry was evicted to stay under the Cache's
+	// size cap.
+	ReasonEvicted Reason = iota
+	// ReasonExpired means the entry's TTL had elapsed.
+	ReasonExpired
+	// ReasonDeleted means the entry was removed by an explicit Delete.
+	ReasonDeleted
+)
+
+// cacheEntry is one cache slot, doubly-linked into its shard's LRU list. ref
+// is set by Get and cleared by the shard's CLOCK eviction scan, so Get never
+// needs to touch the list itself.
+type cacheEntry[K comparable, V any] struct {
+	key        K
+	value      V
+	expiresAt  int64 // UnixNano; 0 means no TTL
+	ref        int32 // CLOCK "referenced" bit
+	prev, next *cacheEntry[K, V]
+}
+
+func (e *cacheEntry[K, V]) expired(now int64) bool {
+	return e.expiresAt != 0 && now >= e.expiresAt
+}
+
+// cacheShard is one shard of a Cache: a plain Go map for O(1) lookup plus an
+// intrusive doubly-linked LRU list, so eviction and expiry sweeps never need
+// to look outside the shard they're working on.
+type cacheShard[K comparable, V any] struct {
+	cap        int
+	items      map[K]*cacheEntry[K, V]
+	head, tail *cacheEntry[K, V] // head = most recently used
+}
+
+func newCacheShard[K comparable, V any](cap int) *cacheShard[K, V] {
+	return &cacheShard[K, V]{cap: cap, items: make(map[K]*cacheEntry[K, V])}
+}
+
+func (s *cacheShard[K, V]) pushFront(e *cacheEntry[K, V]) {
+	e.prev = nil
+	e.next = s.head
+	if s.head != nil {
+		s.head.prev = e
+	}
+	s.head = e
+	if s.tail == nil {
+		s.tail = e
+	}
+}
+
+func (s *cacheShard[K, V]) unlink(e *cacheEntry[K, V]) {
+	if e.prev != nil {
+		e.prev.next = e.next
+	} else {
+		s.head = e.next
+	}
+	if e.next != nil {
+		e.next.prev = e.prev
+	} else {
+		s.tail = e.prev
+	}
+	e.prev, e.next = nil, nil
+}
+
+func (s *cacheShard[K, V]) moveToFront(e *cacheEntry[K, V]) {
+	if s.head == e {
+		return
+	}
+	s.unlink(e)
+	s.pushFront(e)
+}
+
+// CacheOptions configures NewCache.
+type CacheOptions struct {
+	// Cap is the global maximum number of entries, enforced by giving each
+	// shard a proportional share (Cap/shards) rather than a single global
+	// counter, so Set never needs to lock more than its own shard. When Cap
+	// is smaller than the shard count, every shard still gets a floor of 1
+	// rather than truncating to an unbounded 0, so the effective cap is
+	// max(Cap, shards) rounded up to a multiple of shards. Cap <= 0 means
+	// unbounded; only TTL expiry reclaims entries.
+	Cap int
+	// JanitorInterval is how often each shard's background goroutine sweeps
+	// for TTL-expired entries. Zero disables the janitor; expiry is still
+	// enforced lazily on Get.
+	JanitorInterval time.Duration
+}
+
+// Cache is an LRU/TTL eviction layer sharded the same way Map is: each
+// shard owns its own LRU list and enforces its own share of the global size
+// cap, so the hot Get path stays a single RLock plus a lazy expiry check.
+type Cache[K comparable, V any] struct {
+	shards     int
+	shardIDMax uint64
+	hasher     Hasher[K]
+	mus        []sync.RWMutex
+	data       []*cacheShard[K, V]
+	onEvict    atomic.Pointer[func(key K, value V, reason Reason)]
+	janitor    time.Duration
+	stop       chan struct{}
+	wg         sync.WaitGroup
+}
+
+// NewCache returns a new Cache configured by opts.
+func NewCache[K comparable, V any](opts CacheOptions) *Cache[K, V] {
+	c := &Cache[K, V]{
+		hasher:  detectHasher[K](),
+		janitor: opts.JanitorInterval,
+		stop:    make(chan struct{}),
+	}
+	noop := func(K, V, Reason) {}
+	c.onEvict.Store(&noop)
+	c.shards = 1
+	for c.shards < runtime.NumCPU()*16 {
+		c.shards *= 2
+	}
+	c.shardIDMax = uint64(c.shards - 1)
+	var scap int
+	if opts.Cap > 0 {
+		scap = opts.Cap / c.shards
+		if scap < 1 {
+			scap = 1
+		}
+	}
+	c.mus = make([]sync.RWMutex, c.shards)
+	c.data = make([]*cacheShard[K, V], c.shards)
+	for i := range c.data {
+		c.data[i] = newCacheShard[K, V](scap)
+	}
+	if c.janitor > 0 {
+		c.startJanitor()
+	}
+	return c
+}
+
+// OnEvict registers fn to be called whenever an entry leaves the cache,
+// whether by size eviction, TTL expiry, or explicit Delete. fn must not call
+// back into the Cache. Size evictions call fn from the Set goroutine that
+// triggered them, while expiry calls fn from a per-shard janitor goroutine,
+// so fn must be safe to call concurrently from multiple goroutines. OnEvict
+// itself is also safe to call concurrently with evictions already in flight
+// (the swap is atomic), though callers wanting every eviction to see a fixed
+// fn should register it before the Cache sees any traffic.
+func (c *Cache[K, V]) OnEvict(fn func(key K, value V, reason Reason)) {
+	if fn == nil {
+		fn = func(K, V, Reason) {}
+	}
+	c.onEvict.Store(&fn)
+}
+
+func (c *Cache[K, V]) fireEvict(key K, value V, reason Reason) {
+	fn := *c.onEvict.Load()
+	fn(key, value, reason)
+}
+
+func (c *Cache[K, V]) shardFor(key K) int {
+	return int(c.hasher.Hash(key) & c.shardIDMax)
+}
+
+// Set assigns a value to a key with no expiry.
+func (c *Cache[K, V]) Set(key K, value V) {
+	c.SetWithTTL(key, value, 0)
+}
+
+// SetWithTTL assigns a value to a key that expires after ttl. A ttl <= 0
+// means the entry never expires.
+func (c *Cache[K, V]) SetWithTTL(key K, value V, ttl time.Duration) {
+	var expiresAt int64
+	if ttl > 0 {
+		expiresAt = time.Now().Add(ttl).UnixNano()
+	}
+
+	i := c.shardFor(key)
+	c.mus[i].Lock()
+	s := c.data[i]
+	if e, ok := s.items[key]; ok {
+		e.value = value
+		e.expiresAt = expiresAt
+		atomic.StoreInt32(&e.ref, 1)
+		s.moveToFront(e)
+		c.mus[i].Unlock()
+		return
+	}
+
+	e := &cacheEntry[K, V]{key: key, value: value, expiresAt: expiresAt}
+	s.items[key] = e
+	s.pushFront(e)
+
+	var evicted []*cacheEntry[K, V]
+	for s.cap > 0 && len(s.items) > s.cap {
+		victim := c.evictOne(s)
+		if victim == nil {
+			break
+		}
+		evicted = append(evicted, victim)
+	}
+	c.mus[i].Unlock()
+
+	for _, v := range evicted {
+		c.fireEvict(v.key, v.value, ReasonEvicted)
+	}
+}
+
+// evictOne removes and returns the CLOCK-algorithm victim from s: starting
+// at the tail, any entry whose ref bit was set by a Get since it last passed
+// through here is given a second chance (ref cleared, moved to front)
+// instead of being evicted. This approximates LRU while letting Get record
+// recency with a single atomic store instead of relocking the list.
+func (c *Cache[K, V]) evictOne(s *cacheShard[K, V]) *cacheEntry[K, V] {
+	for n := len(s.items); n > 0; n-- {
+		e := s.tail
+		if e == nil {
+			return nil
+		}
+		if atomic.CompareAndSwapInt32(&e.ref, 1, 0) {
+			s.moveToFront(e)
+			continue
+		}
+		delete(s.items, e.key)
+		s.unlink(e)
+		return e
+	}
+	return nil
+}
+
+// Get returns a value for a key, or false if it's absent or its TTL has
+// elapsed.
+func (c *Cache[K, V]) Get(key K) (value V, ok bool) {
+	value, _, ok = c.GetWithExpiry(key)
+	return value, ok
+}
+
+// GetWithExpiry returns a value for a key along with its expiry time (the
+// zero Time if it has none), or false if the key is absent or its TTL has
+// lazily been found to have elapsed. It never takes more than the shard's
+// RLock.
+func (c *Cache[K, V]) GetWithExpiry(key K) (value V, expiry time.Time, ok bool) {
+	i := c.shardFor(key)
+	c.mus[i].RLock()
+	defer c.mus[i].RUnlock()
+
+	e, found := c.data[i].items[key]
+	if !found || e.expired(time.Now().UnixNano()) {
+		return value, expiry, false
+	}
+	atomic.StoreInt32(&e.ref, 1)
+	value = e.value
+	if e.expiresAt != 0 {
+		expiry = time.Unix(0, e.expiresAt)
+	}
+	return value, expiry, true
+}
+
+// Delete removes a key immediately, firing OnEvict with ReasonDeleted if it
+// was present.
+func (c *Cache[K, V]) Delete(key K) {
+	i := c.shardFor(key)
+	c.mus[i].Lock()
+	s := c.data[i]
+	e, ok := s.items[key]
+	if ok {
+		delete(s.items, key)
+		s.unlink(e)
+	}
+	c.mus[i].Unlock()
+
+	if ok {
+		c.fireEvict(e.key, e.value, ReasonDeleted)
+	}
+}
+
+// Len returns the number of entries in the cache, including any that have
+// expired but haven't yet been swept by the janitor or touched by Get.
+func (c *Cache[K, V]) Len() int {
+	var n int
+	for i := range c.data {
+		c.mus[i].RLock()
+		n += len(c.data[i].items)
+		c.mus[i].RUnlock()
+	}
+	return n
+}
+
+// Close stops the Cache's background janitor goroutines. The Cache remains
+// usable afterward; only proactive expiry sweeps stop, and Get keeps
+// enforcing TTLs lazily.
+func (c *Cache[K, V]) Close() {
+	select {
+	case <-c.stop:
+		return
+	default:
+		close(c.stop)
+	}
+	c.wg.Wait()
+}
+
+func (c *Cache[K, V]) startJanitor() {
+	for i := range c.data {
+		c.wg.Add(1)
+		go c.runJanitor(i)
+	}
+}
+
+func (c *Cache[K, V]) runJanitor(i int) {
+	defer c.wg.Done()
+	t := time.NewTicker(c.janitor)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+			c.sweepExpired(i)
+		case <-c.stop:
+			return
+		}
+	}
+}
+
+func (c *Cache[K, V]) sweepExpired(i int) {
+	now := time.Now().UnixNano()
+
+	var expired []*cacheEntry[K, V]
+	c.mus[i].Lock()
+	s := c.data[i]
+	for e := s.tail; e != nil; {
+		prev := e.prev
+		if e.expired(now) {
+			delete(s.items, e.key)
+			s.unlink(e)
+			expired = append(expired, e)
+		}
+		e = prev
+	}
+	c.mus[i].Unlock()
+
+	for _, e := range expired {
+		c.fireEvict(e.key, e.value, ReasonExpired)
+	}
+}
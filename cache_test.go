@@ -0,0 +1,50 @@
+package shardmap
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCacheOnEvictConcurrentWithEviction calls OnEvict on one goroutine while
+// Set-triggered evictions and the TTL janitor are both firing the callback
+// on other goroutines, reproducing the race the unsynchronized onEvict field
+// used to hit under -race whenever OnEvict was called after NewCache started
+// the janitor.
+func TestCacheOnEvictConcurrentWithEviction(t *testing.T) {
+	c := NewCache[int, int](CacheOptions{Cap: 100, JanitorInterval: time.Millisecond})
+	defer c.Close()
+
+	var wg sync.WaitGroup
+	stop := make(chan struct{})
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; ; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			c.SetWithTTL(i%1000, i, time.Millisecond)
+		}
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 1000; i++ {
+			select {
+			case <-stop:
+				return
+			default:
+			}
+			c.OnEvict(func(key, value int, reason Reason) {})
+		}
+	}()
+
+	time.Sleep(20 * time.Millisecond)
+	close(stop)
+	wg.Wait()
+}
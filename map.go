@@ -3,22 +3,38 @@ package shardmap
 import (
 	"runtime"
 	"sync"
-	"unsafe"
-
-	"github.com/zeebo/xxh3"
 )
 
+// shardStore is the per-shard storage backing a Map. mapShard guards every
+// access with the owning Map's per-shard sync.RWMutex; atomicShard only
+// relies on that mutex for writers and lets GetWithHash run lock-free.
+type shardStore[K comparable, V any] interface {
+	SetWithHash(hash uint64, key K, value V) (V, bool)
+	GetWithHash(hash uint64, key K) (V, bool)
+	DeleteWithHash(hash uint64, key K) (V, bool)
+	Len() int
+	Scan(iter func(key K, value V) bool)
+}
+
+// Options configures a Map created with NewWithOptions.
+type Options struct {
+	// LockFreeReads selects an atomic-pointer shard implementation so Get
+	// never takes the per-shard sync.RWMutex. Writers still serialize on
+	// that mutex as usual; only the hot read path changes.
+	LockFreeReads bool
+}
+
 // Map is a hashmap. Like map[string]interface{}, but sharded and thread-safe.
 type Map[K comparable, V any] struct {
 	init       sync.Once
 	cap        int
+	opts       Options
 	shards     int
 	shardIDMax uint64
 	seed       uint64
 	mus        []sync.RWMutex
-	maps       []*mapShard[K, V]
-	kstr       bool
-	ksize      int
+	maps       []shardStore[K, V]
+	hasher     Hasher[K]
 }
 
 // New returns a new hashmap with the specified capacity. This function is only
@@ -30,20 +46,37 @@ func New[K comparable, V any](cap int) *Map[K, V] {
 	return m
 }
 
-func (m *Map[K, V]) detectHasher() {
-	// Detect the key type. This is needed by the hasher.
-	var k K
-	switch ((interface{})(k)).(type) {
-	case string:
-		m.kstr = true
-	default:
-		m.ksize = int(unsafe.Sizeof(k))
+// NewWithOptions returns a new hashmap with the specified capacity and
+// Options, e.g. NewWithOptions[K, V](0, Options{LockFreeReads: true}) for a
+// Map whose Get never locks.
+func NewWithOptions[K comparable, V any](cap int, opts Options) *Map[K, V] {
+	m := &Map[K, V]{cap: cap, opts: opts}
+	m.Init()
+	return m
+}
+
+// NewWithHasher returns a new hashmap that hashes keys with h instead of the
+// auto-detected Hasher, which is useful when K is a composite key whose
+// auto-detected structHasher is too slow for a hot path and a faster
+// domain-specific Hasher is available.
+func NewWithHasher[K comparable, V any](cap int, h Hasher[K]) *Map[K, V] {
+	m := &Map[K, V]{cap: cap, hasher: h}
+	m.Init()
+	return m
+}
+
+func (m *Map[K, V]) newShard(cap int) shardStore[K, V] {
+	if m.opts.LockFreeReads {
+		return newAtomicShard[K, V](cap)
 	}
+	return newShard[K, V](cap)
 }
 
 func (m *Map[K, V]) Init() {
 	m.init.Do(func() {
-		m.detectHasher()
+		if m.hasher == nil {
+			m.hasher = detectHasher[K]()
+		}
 		m.shards = 1
 		for m.shards < runtime.NumCPU()*16 {
 			m.shards *= 2
@@ -51,9 +84,9 @@ func (m *Map[K, V]) Init() {
 		m.shardIDMax = uint64(m.shards - 1)
 		scap := m.cap / m.shards
 		m.mus = make([]sync.RWMutex, m.shards)
-		m.maps = make([]*mapShard[K, V], m.shards)
+		m.maps = make([]shardStore[K, V], m.shards)
 		for i := 0; i < len(m.maps); i++ {
-			m.maps[i] = newShard[K, V](scap)
+			m.maps[i] = m.newShard(scap)
 		}
 	})
 }
@@ -62,7 +95,7 @@ func (m *Map[K, V]) Init() {
 func (m *Map[K, V]) Clear() {
 	for i := 0; i < m.shards; i++ {
 		m.mus[i].Lock()
-		m.maps[i] = newShard[K, V](m.cap / m.shards)
+		m.maps[i] = m.newShard(m.cap / m.shards)
 		m.mus[i].Unlock()
 	}
 }
@@ -111,6 +144,10 @@ func (m *Map[K, V]) SetAccept(
 // Returns false when no value has been assign for key.
 func (m *Map[K, V]) Get(key K) (value V, ok bool) {
 	shard, shardKey := m.choose(key)
+	if m.opts.LockFreeReads {
+		// atomicShard.GetWithHash is safe to call without the shard lock.
+		return m.maps[shard].GetWithHash(shardKey, key)
+	}
 	m.mus[shard].RLock()
 	value, ok = m.maps[shard].GetWithHash(shardKey, key)
 	m.mus[shard].RUnlock()
@@ -189,15 +226,6 @@ func (m *Map[K, V]) Range(iter func(key K, value V) bool) {
 }
 
 func (m *Map[K, V]) choose(key K) (shard, hashkey uint64) {
-	var strKey string
-	if m.kstr {
-		strKey = *(*string)(unsafe.Pointer(&key))
-	} else {
-		strKey = *(*string)(unsafe.Pointer(&struct {
-			data unsafe.Pointer
-			len  int
-		}{unsafe.Pointer(&key), m.ksize}))
-	}
-	gkey := xxh3.HashString(strKey)
+	gkey := m.hasher.Hash(key)
 	return gkey & m.shardIDMax, makeHash(gkey)
 }
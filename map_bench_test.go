@@ -0,0 +1,124 @@
+package shardmap
+
+import "testing"
+
+// shardVariants lets each benchmark below run once per shardStore
+// implementation, so `go test -bench . -benchmem` directly compares the
+// lock-free atomicShard against the baseline RWMutex-guarded mapShard for
+// the same workload.
+var shardVariants = map[string]func(cap int) *Map[int, int]{
+	"RWMutex":  func(cap int) *Map[int, int] { return NewWithOptions[int, int](cap, Options{}) },
+	"LockFree": func(cap int) *Map[int, int] { return NewWithOptions[int, int](cap, Options{LockFreeReads: true}) },
+}
+
+const benchMapSize = 1 << 16
+
+// BenchmarkLookupPositive measures Get for keys that are always present, the
+// hot path LockFreeReads exists for.
+func BenchmarkLookupPositive(b *testing.B) {
+	for name, newMap := range shardVariants {
+		b.Run(name, func(b *testing.B) {
+			m := newMap(benchMapSize)
+			for i := 0; i < benchMapSize; i++ {
+				m.Set(i, i)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					m.Get(i % benchMapSize)
+					i++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkLookupNegative measures Get for keys that are never present, so
+// every probe runs to the end of its chain.
+func BenchmarkLookupNegative(b *testing.B) {
+	for name, newMap := range shardVariants {
+		b.Run(name, func(b *testing.B) {
+			m := newMap(benchMapSize)
+			for i := 0; i < benchMapSize; i++ {
+				m.Set(i, i)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					m.Get(benchMapSize + i%benchMapSize)
+					i++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkStoreDelete measures back-to-back Set/Delete of the same key,
+// the pattern that drives Robin Hood displacement and chain shifting.
+func BenchmarkStoreDelete(b *testing.B) {
+	for name, newMap := range shardVariants {
+		b.Run(name, func(b *testing.B) {
+			m := newMap(0)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					m.Set(i, i)
+					m.Delete(i)
+					i++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkLoadOrStoreDelete measures a Get-then-Set-if-absent pattern
+// (the closest thing this Map has to sync.Map's LoadOrStore) followed by a
+// Delete, so both the read and write paths are exercised per iteration.
+func BenchmarkLoadOrStoreDelete(b *testing.B) {
+	for name, newMap := range shardVariants {
+		b.Run(name, func(b *testing.B) {
+			m := newMap(0)
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					if _, ok := m.Get(i); !ok {
+						m.Set(i, i)
+					}
+					m.Delete(i)
+					i++
+				}
+			})
+		})
+	}
+}
+
+// BenchmarkConcurrent measures a mixed read/write workload: every tenth
+// operation is a Set, the rest are Get, against a map pre-populated with
+// benchMapSize keys so most Gets hit.
+func BenchmarkConcurrent(b *testing.B) {
+	for name, newMap := range shardVariants {
+		b.Run(name, func(b *testing.B) {
+			m := newMap(benchMapSize)
+			for i := 0; i < benchMapSize; i++ {
+				m.Set(i, i)
+			}
+			b.ResetTimer()
+			b.RunParallel(func(pb *testing.PB) {
+				i := 0
+				for pb.Next() {
+					key := i % benchMapSize
+					if i%10 == 0 {
+						m.Set(key, i)
+					} else {
+						m.Get(key)
+					}
+					i++
+				}
+			})
+		})
+	}
+}
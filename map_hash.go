@@ -0,0 +1,202 @@
+package shardmap
+
+import (
+	"reflect"
+	"unsafe"
+
+	"github.com/zeebo/xxh3"
+)
+
+// Hasher computes a 64-bit hash for a Map's key type K. detectHasher
+// auto-selects one of the built-in hashers below based on
+// reflect.TypeOf(k).Kind(), so existing call sites keep working without
+// specifying one; NewWithHasher lets callers override the choice, which is
+// useful for hot paths where the auto-detected reflect-based structHasher is
+// too slow.
+type Hasher[K comparable] interface {
+	Hash(key K) uint64
+}
+
+// stringHasher hashes K values that are themselves strings (or named string
+// types) by their content. detectHasher only selects it once reflect has
+// confirmed K's Kind() is String, so the unsafe cast below only ever
+// reinterprets a real string header, not an arbitrary comparable's bytes.
+type stringHasher[K comparable] struct{}
+
+func (stringHasher[K]) Hash(key K) uint64 {
+	return xxh3.HashString(*(*string)(unsafe.Pointer(&key)))
+}
+
+// fixedHasher hashes K values whose in-memory representation has no padding
+// and no pointer/interface bytes, e.g. plain fixed-width ints and [N]byte
+// arrays. detectHasher only ever selects it for kinds known to be safe this
+// way; composite keys go through structHasher instead.
+type fixedHasher[K comparable] struct {
+	size int
+}
+
+func (h fixedHasher[K]) Hash(key K) uint64 {
+	return xxh3.Hash(unsafe.Slice((*byte)(unsafe.Pointer(&key)), h.size))
+}
+
+// float32Hasher and float64Hasher hash K values that are themselves floats.
+// They can't go through fixedHasher's raw byte hash because +0.0 and -0.0
+// are == under Go's comparable semantics but have different IEEE bit
+// patterns; canonicalizing -0.0 to +0.0 before hashing keeps the hash
+// consistent with that equality.
+type float32Hasher[K comparable] struct{}
+
+func (float32Hasher[K]) Hash(key K) uint64 {
+	f := *(*float32)(unsafe.Pointer(&key))
+	if f == 0 {
+		f = 0
+	}
+	return xxh3.Hash(unsafe.Slice((*byte)(unsafe.Pointer(&f)), 4))
+}
+
+type float64Hasher[K comparable] struct{}
+
+func (float64Hasher[K]) Hash(key K) uint64 {
+	f := *(*float64)(unsafe.Pointer(&key))
+	if f == 0 {
+		f = 0
+	}
+	return xxh3.Hash(unsafe.Slice((*byte)(unsafe.Pointer(&f)), 8))
+}
+
+// structHasher hashes comparable struct keys by walking their fields with
+// reflect and feeding each field's meaningful bytes to a running digest
+// individually, rather than hashing the struct's raw memory. That avoids two
+// bugs a whole-struct byte hash has: padding bytes between fields are
+// uninitialized and can differ between two logically equal values, and an
+// interface field's data word is a pointer to a separately boxed copy, so
+// hashing it raw hashes address bits instead of the boxed value.
+type structHasher[K comparable] struct{}
+
+func (structHasher[K]) Hash(key K) uint64 {
+	d := xxh3.New()
+	hashValue(d, reflect.ValueOf(key))
+	return d.Sum64()
+}
+
+func hashValue(d *xxh3.Hasher, v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Invalid:
+		// reflect.ValueOf(nil) for a nil interface key (K itself interface,
+		// e.g. Map[any, V]): a plain Go map accepts a nil key, so this must
+		// too. There's no Type to read, just hash a sentinel distinct from
+		// the Interface case's own nil encoding (a single 0 byte).
+		d.Write([]byte{0xff})
+	case reflect.String:
+		// Length-prefixed so back-to-back variable-length fields can't blur
+		// together, e.g. {A:"ab",B:""} vs {A:"a",B:"b"}.
+		s := v.String()
+		writeFixed(d, uint64(len(s)))
+		d.WriteString(s)
+	case reflect.Bool:
+		if v.Bool() {
+			d.Write([]byte{1})
+		} else {
+			d.Write([]byte{0})
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		writeFixed(d, v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		writeFixed(d, v.Uint())
+	case reflect.Float32:
+		f := float32(v.Float())
+		if f == 0 {
+			f = 0 // canonicalize -0.0 to +0.0, which are == under comparable
+		}
+		writeFixed(d, f)
+	case reflect.Float64:
+		f := v.Float()
+		if f == 0 {
+			f = 0
+		}
+		writeFixed(d, f)
+	case reflect.Ptr:
+		// Go compares pointer keys by address, so hashing the address is
+		// sound; unlike an interface field, there's no boxed-copy ambiguity.
+		var p uintptr
+		if !v.IsNil() {
+			p = v.Pointer()
+		}
+		writeFixed(d, p)
+	case reflect.Interface:
+		if v.IsNil() {
+			d.Write([]byte{0})
+			return
+		}
+		d.Write([]byte{1})
+		hashValue(d, v.Elem())
+	case reflect.Array:
+		for i := 0; i < v.Len(); i++ {
+			hashValue(d, v.Index(i))
+		}
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			hashValue(d, v.Field(i))
+		}
+	default:
+		// Anything else reaching here (float, complex, chan) is still
+		// comparable and padding-free on its own, so hash it directly.
+		tmp := reflect.New(v.Type()).Elem()
+		tmp.Set(v)
+		d.Write(unsafe.Slice((*byte)(tmp.Addr().UnsafePointer()), tmp.Type().Size()))
+	}
+}
+
+func writeFixed[T any](d *xxh3.Hasher, v T) {
+	d.Write(unsafe.Slice((*byte)(unsafe.Pointer(&v)), unsafe.Sizeof(v)))
+}
+
+// detectHasher picks a Hasher[K] for K based on its reflect.Kind. Composite
+// kinds that could hide pointers/interfaces behind padding (struct, or an
+// array of them) go through the reflect-driven structHasher; everything with
+// a known-safe flat layout uses the cheaper fixedHasher.
+func detectHasher[K comparable]() Hasher[K] {
+	var k K
+	t := reflect.TypeOf(k)
+	if t == nil {
+		// K is itself an interface type; fall back to the field walker, which
+		// already knows how to hash through interface values.
+		return structHasher[K]{}
+	}
+	switch t.Kind() {
+	case reflect.String:
+		return stringHasher[K]{}
+	case reflect.Float32:
+		return float32Hasher[K]{}
+	case reflect.Float64:
+		return float64Hasher[K]{}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr, reflect.Bool, reflect.Ptr:
+		return fixedHasher[K]{size: int(t.Size())}
+	case reflect.Array:
+		if arrayIsFlat(t) {
+			return fixedHasher[K]{size: int(t.Size())}
+		}
+		return structHasher[K]{}
+	default:
+		return structHasher[K]{}
+	}
+}
+
+// arrayIsFlat reports whether every element of array type t is itself a
+// fixed-width, pointer-free kind, making a raw byte hash of the whole array
+// sound (e.g. [16]byte, [4]uint32). Floats are excluded even though they're
+// fixed-width and pointer-free: -0.0 and +0.0 are == but have different IEEE
+// bit patterns, so a float array must go through structHasher's per-element
+// hashValue (which canonicalizes) instead of a whole-array raw byte hash.
+func arrayIsFlat(t reflect.Type) bool {
+	switch t.Elem().Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr, reflect.Bool:
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,51 @@
+package shardmap
+
+import (
+	"math"
+	"testing"
+)
+
+func TestStructHasherFramesVariableLengthFields(t *testing.T) {
+	type K struct {
+		A string
+		B string
+	}
+	h := structHasher[K]{}
+	h1 := h.Hash(K{A: "ab", B: ""})
+	h2 := h.Hash(K{A: "a", B: "b"})
+	if h1 == h2 {
+		t.Fatalf("unframed variable-length fields collide: Hash(%+v) == Hash(%+v) == %d", K{A: "ab"}, K{A: "a", B: "b"}, h1)
+	}
+}
+
+// TestNilInterfaceKey exercises Map[any, V] with a nil key, which a plain
+// Go map accepts; hashValue used to panic on it via reflect.ValueOf(nil).
+func TestNilInterfaceKey(t *testing.T) {
+	m := New[any, int](0)
+	m.Set(nil, 42)
+	v, ok := m.Get(nil)
+	if !ok || v != 42 {
+		t.Fatalf("Get(nil) = %v, %v; want 42, true", v, ok)
+	}
+	m.Set("not nil", 7)
+	v, ok = m.Get(nil)
+	if !ok || v != 42 {
+		t.Fatalf("Get(nil) after other inserts = %v, %v; want 42, true", v, ok)
+	}
+}
+
+// TestFloatNegativeZeroKey confirms -0.0 and +0.0, which are == under Go's
+// comparable semantics, hash to the same shard/bucket the way builtin
+// map[float64]V treats them as one key.
+func TestFloatNegativeZeroKey(t *testing.T) {
+	m := New[float64, int](0)
+	m.Set(0.0, 1)
+	m.Set(math.Copysign(0, -1), 2)
+	if got := m.Len(); got != 1 {
+		t.Fatalf("Len() = %d, want 1 (builtin map[float64]int treats +0.0/-0.0 as one key)", got)
+	}
+	v, ok := m.Get(0.0)
+	if !ok || v != 2 {
+		t.Fatalf("Get(0.0) = %v, %v; want 2, true", v, ok)
+	}
+}
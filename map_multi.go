@@ -0,0 +1,149 @@
+package shardmap
+
+import (
+	"runtime"
+	"sync"
+)
+
+// multiBatchThreshold is the number of input keys/entries above which
+// GetMulti/SetMulti/DeleteMulti dispatch per-shard work across a worker
+// pool instead of running shard-by-shard on the caller's goroutine.
+const multiBatchThreshold = 1024
+
+// dispatchShards calls work once per shard in [0,shards). For small batches
+// (n < multiBatchThreshold) it just loops; for large ones it fans the calls
+// out across a worker pool sized to runtime.NumCPU(), since each call only
+// touches its own shard's lock and entries.
+func dispatchShards(n, shards int, work func(shard int)) {
+	if n < multiBatchThreshold {
+		for i := 0; i < shards; i++ {
+			work(i)
+		}
+		return
+	}
+
+	workers := runtime.NumCPU()
+	if workers > shards {
+		workers = shards
+	}
+	shardCh := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range shardCh {
+				work(i)
+			}
+		}()
+	}
+	for i := 0; i < shards; i++ {
+		shardCh <- i
+	}
+	close(shardCh)
+	wg.Wait()
+}
+
+// keyHash pairs a key with its precomputed shard hash, so a batch of keys
+// only ever runs choose once per key no matter how many shard operations
+// follow.
+type keyHash[K comparable] struct {
+	key  K
+	hash uint64
+}
+
+// bucketKeys runs choose once per key and groups the results by shard.
+func (m *Map[K, V]) bucketKeys(keys []K) [][]keyHash[K] {
+	buckets := make([][]keyHash[K], m.shards)
+	for _, key := range keys {
+		shard, hash := m.choose(key)
+		buckets[shard] = append(buckets[shard], keyHash[K]{key, hash})
+	}
+	return buckets
+}
+
+// entryHash pairs a key/value pair with its precomputed shard hash.
+type entryHash[K comparable, V any] struct {
+	key   K
+	value V
+	hash  uint64
+}
+
+// bucketEntries runs choose once per key and groups the entries by shard.
+func (m *Map[K, V]) bucketEntries(entries map[K]V) [][]entryHash[K, V] {
+	buckets := make([][]entryHash[K, V], m.shards)
+	for key, value := range entries {
+		shard, hash := m.choose(key)
+		buckets[shard] = append(buckets[shard], entryHash[K, V]{key, value, hash})
+	}
+	return buckets
+}
+
+// GetMulti returns the values assigned to keys. Keys are bucketed by shard
+// first, so each shard is locked (RLock) only once no matter how many of
+// keys it owns. Keys with no assigned value are simply absent from the
+// result.
+func (m *Map[K, V]) GetMulti(keys []K) map[K]V {
+	m.Init()
+	buckets := m.bucketKeys(keys)
+	result := make(map[K]V, len(keys))
+	var mu sync.Mutex
+	dispatchShards(len(keys), m.shards, func(i int) {
+		bucket := buckets[i]
+		if len(bucket) == 0 {
+			return
+		}
+		m.mus[i].RLock()
+		found := make(map[K]V, len(bucket))
+		for _, kh := range bucket {
+			if v, ok := m.maps[i].GetWithHash(kh.hash, kh.key); ok {
+				found[kh.key] = v
+			}
+		}
+		m.mus[i].RUnlock()
+
+		mu.Lock()
+		for k, v := range found {
+			result[k] = v
+		}
+		mu.Unlock()
+	})
+	return result
+}
+
+// SetMulti assigns values to keys. Entries are bucketed by shard first, so
+// each shard is locked (Lock) only once no matter how many of entries it
+// owns.
+func (m *Map[K, V]) SetMulti(entries map[K]V) {
+	m.Init()
+	buckets := m.bucketEntries(entries)
+	dispatchShards(len(entries), m.shards, func(i int) {
+		bucket := buckets[i]
+		if len(bucket) == 0 {
+			return
+		}
+		m.mus[i].Lock()
+		for _, e := range bucket {
+			m.maps[i].SetWithHash(e.hash, e.key, e.value)
+		}
+		m.mus[i].Unlock()
+	})
+}
+
+// DeleteMulti deletes keys. Keys are bucketed by shard first, so each shard
+// is locked (Lock) only once no matter how many of keys it owns.
+func (m *Map[K, V]) DeleteMulti(keys []K) {
+	m.Init()
+	buckets := m.bucketKeys(keys)
+	dispatchShards(len(keys), m.shards, func(i int) {
+		bucket := buckets[i]
+		if len(bucket) == 0 {
+			return
+		}
+		m.mus[i].Lock()
+		for _, kh := range bucket {
+			m.maps[i].DeleteWithHash(kh.hash, kh.key)
+		}
+		m.mus[i].Unlock()
+	})
+}
@@ -0,0 +1,109 @@
+package shardmap
+
+import (
+	"runtime"
+	"sync"
+)
+
+// RangeParallel iterates over all key/values, scanning shards concurrently
+// across workers goroutines (runtime.NumCPU() when workers <= 0). Like
+// Range, it's not safe to call Set or Delete on a shard while it's being
+// scanned, and iter must be safe for concurrent use since multiple shards
+// may be calling it at once. Outstanding workers are cancelled as soon as
+// iter returns false.
+func (m *Map[K, V]) RangeParallel(iter func(key K, value V) bool, workers int) {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if workers > m.shards {
+		workers = m.shards
+	}
+
+	shardCh := make(chan int)
+	stop := make(chan struct{})
+	var stopOnce sync.Once
+	cancel := func() { stopOnce.Do(func() { close(stop) }) }
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range shardCh {
+				func() {
+					m.mus[i].RLock()
+					defer m.mus[i].RUnlock()
+					m.maps[i].Scan(func(key K, value V) bool {
+						select {
+						case <-stop:
+							return false
+						default:
+						}
+						if !iter(key, value) {
+							cancel()
+							return false
+						}
+						return true
+					})
+				}()
+			}
+		}()
+	}
+
+	for i := 0; i < m.shards; i++ {
+		select {
+		case shardCh <- i:
+		case <-stop:
+			close(shardCh)
+			wg.Wait()
+			return
+		}
+	}
+	close(shardCh)
+	wg.Wait()
+}
+
+// snapshotEntry is one key/value pair captured by Snapshot.
+type snapshotEntry[K comparable, V any] struct {
+	key   K
+	value V
+}
+
+// Snapshot is a point-in-time copy of a Map's entries. Unlike Range, it's
+// safe to walk a Snapshot while Set/Delete continue running on the live Map.
+type Snapshot[K comparable, V any] struct {
+	entries []snapshotEntry[K, V]
+}
+
+// Snapshot copies each shard's live entries under that shard's RLock and
+// returns a Snapshot that can be ranged over without blocking concurrent
+// writers on the live Map. Useful for stats/export workloads that would
+// otherwise need to hold Range's iteration lock for a long time.
+func (m *Map[K, V]) Snapshot() *Snapshot[K, V] {
+	s := &Snapshot[K, V]{}
+	for i := 0; i < m.shards; i++ {
+		func() {
+			m.mus[i].RLock()
+			defer m.mus[i].RUnlock()
+			m.maps[i].Scan(func(key K, value V) bool {
+				s.entries = append(s.entries, snapshotEntry[K, V]{key, value})
+				return true
+			})
+		}()
+	}
+	return s
+}
+
+// Range iterates over all key/values captured in the snapshot.
+func (s *Snapshot[K, V]) Range(iter func(key K, value V) bool) {
+	for _, e := range s.entries {
+		if !iter(e.key, e.value) {
+			return
+		}
+	}
+}
+
+// Len returns the number of entries captured in the snapshot.
+func (s *Snapshot[K, V]) Len() int {
+	return len(s.entries)
+}
@@ -0,0 +1,240 @@
+package shardmap
+
+import "sync/atomic"
+
+// atomicBucket is a single probe slot whose entry pointer is published with
+// atomic loads/stores, so GetWithHash never needs to take the shard's
+// sync.RWMutex.
+type atomicBucket[K comparable, V any] struct {
+	p atomic.Pointer[entry[K, V]]
+}
+
+// atomicState is the bucket array for an atomicShard at a point in time.
+// Resizing builds a new atomicState and publishes it with a single pointer
+// swap so in-flight readers keep seeing a consistent table.
+type atomicState[K comparable, V any] struct {
+	mask    uint64
+	buckets []atomicBucket[K, V]
+}
+
+func newAtomicState[K comparable, V any](sz int) *atomicState[K, V] {
+	return &atomicState[K, V]{
+		mask:    uint64(sz - 1),
+		buckets: make([]atomicBucket[K, V], sz),
+	}
+}
+
+// atomicShard is a mapShard alternative modeled on gVisor's AtomicPtrMap:
+// reads snapshot the current atomicState and walk it without locking, while
+// writers (serialized externally by the owning Map's per-shard mutex) publish
+// new entries with atomic stores and shift entries on removal before nil-ing
+// the vacated slot.
+//
+// A single probe pass isn't enough on its own: a reader can load a slot,
+// have a concurrent Robin Hood displacement or removal shift the very key
+// it's looking for into a slot already passed, and wrongly conclude the key
+// is absent even though it was present the whole time. gen is a seqlock-style
+// counter guarding against that: it's odd while such a relocating mutation
+// is in flight and bumped again to even when it completes, so GetWithHash
+// can detect an overlapping mutation and retry instead of trusting a probe
+// that ran concurrently with one.
+type atomicShard[K comparable, V any] struct {
+	cap      int
+	length   int
+	growAt   int
+	shrinkAt int
+	gen      atomic.Uint64
+	state    atomic.Pointer[atomicState[K, V]]
+}
+
+// newAtomicShard returns a new atomicShard.
+func newAtomicShard[K comparable, V any](cap int) *atomicShard[K, V] {
+	m := new(atomicShard[K, V])
+	m.cap = cap
+	sz := 8
+	for sz < m.cap {
+		sz *= 2
+	}
+	m.state.Store(newAtomicState[K, V](sz))
+	m.growAt = int(float64(sz) * loadFactor)
+	m.shrinkAt = int(float64(sz) * (1 - loadFactor))
+	return m
+}
+
+func (m *atomicShard[K, V]) resize(newCap int) {
+	sz := 8
+	for sz < newCap {
+		sz *= 2
+	}
+	nstate := newAtomicState[K, V](sz)
+	ostate := m.state.Load()
+	var discard int
+	for i := range ostate.buckets {
+		e := ostate.buckets[i].p.Load()
+		if e != nil {
+			insertAtomic(nstate, e.hash(), e.key, e.value, &discard)
+		}
+	}
+	m.growAt = int(float64(sz) * loadFactor)
+	m.shrinkAt = int(float64(sz) * (1 - loadFactor))
+	m.state.Store(nstate)
+}
+
+// SetWithHash assigns a value to a key.
+// Returns the previous value, or false when no value was assigned.
+func (m *atomicShard[K, V]) SetWithHash(hash uint64, key K, value V) (V, bool) {
+	if m.state.Load() == nil {
+		m.state.Store(newAtomicState[K, V](0))
+	}
+	if m.length >= m.growAt {
+		m.resize(len(m.state.Load().buckets) * 2)
+	}
+	// insertAtomic can displace and relocate an already-published entry
+	// (Robin Hood), so it's bracketed as one seqlock-guarded mutation for
+	// GetWithHash's benefit; resize's own rehash into a not-yet-published
+	// state doesn't need this since readers can't observe it yet.
+	m.gen.Add(1)
+	prev, ok := insertAtomic(m.state.Load(), hash, key, value, &m.length)
+	m.gen.Add(1)
+	return prev, ok
+}
+
+// insertAtomic performs the Robin Hood insertion, publishing each relocated
+// entry with a release store instead of mutating an already-published entry
+// in place. length is bumped on a fresh insert; resize passes a throwaway
+// counter since it only relocates existing entries.
+func insertAtomic[K comparable, V any](
+	st *atomicState[K, V], hash uint64, key K, value V, length *int,
+) (prev V, ok bool) {
+	e := entry[K, V]{hdib: makeHDIB(hash, 1), value: value, key: key}
+	i := e.hash() & st.mask
+	for {
+		cur := st.buckets[i].p.Load()
+		if cur == nil {
+			ne := e
+			st.buckets[i].p.Store(&ne)
+			*length++
+			return prev, false
+		}
+		if e.hash() == cur.hash() && e.key == cur.key {
+			prev = cur.value
+			ne := entry[K, V]{hdib: cur.hdib, key: cur.key, value: e.value}
+			st.buckets[i].p.Store(&ne)
+			return prev, true
+		}
+		if cur.dib() < e.dib() {
+			displaced := entry[K, V]{hdib: cur.hdib, key: cur.key, value: cur.value}
+			ne := e
+			st.buckets[i].p.Store(&ne)
+			e = displaced
+		}
+		i = (i + 1) & st.mask
+		e.setDIB(e.dib() + 1)
+	}
+}
+
+// GetWithHash returns a value for a key without taking the shard's lock. It
+// snapshots the bucket pointer, verifies the hash and key, and retries the
+// whole probe if a concurrent Robin Hood displacement or removal shift
+// (tracked via gen) overlapped it, so it can't be fooled into reporting a
+// key absent that was actually relocated, not removed.
+func (m *atomicShard[K, V]) GetWithHash(hash uint64, key K) (value V, ok bool) {
+	for {
+		gen0 := m.gen.Load()
+		if gen0&1 == 1 {
+			continue // a relocating mutation is in flight; wait it out
+		}
+		st := m.state.Load()
+		if len(st.buckets) == 0 {
+			return value, false
+		}
+		value, ok = probeAtomic(st, hash, key)
+		if m.gen.Load() == gen0 {
+			return value, ok
+		}
+		// gen moved during the probe: a displacement or shift may have
+		// crossed our path, so the result above can't be trusted. Retry.
+	}
+}
+
+func probeAtomic[K comparable, V any](st *atomicState[K, V], hash uint64, key K) (value V, ok bool) {
+	i := hash & st.mask
+	for {
+		cur := st.buckets[i].p.Load()
+		if cur == nil {
+			return value, false
+		}
+		if cur.hash() == hash && cur.key == key {
+			return cur.value, true
+		}
+		i = (i + 1) & st.mask
+	}
+}
+
+// Len returns the number of values in the shard.
+func (m *atomicShard[K, V]) Len() int {
+	return m.length
+}
+
+// DeleteWithHash deletes a value for a key.
+// Returns the deleted value, or false when no value was assigned.
+func (m *atomicShard[K, V]) DeleteWithHash(hash uint64, key K) (prev V, deleted bool) {
+	st := m.state.Load()
+	if len(st.buckets) == 0 {
+		return prev, false
+	}
+	i := hash & st.mask
+	for {
+		cur := st.buckets[i].p.Load()
+		if cur == nil {
+			return prev, false
+		}
+		if cur.hash() == hash && cur.key == key {
+			prev = cur.value
+			m.remove(st, i)
+			return prev, true
+		}
+		i = (i + 1) & st.mask
+	}
+}
+
+// remove shifts the trailing probe chain back one slot at a time, publishing
+// each shifted entry before nil-ing the slot it vacated. The whole shift is
+// bracketed as one seqlock-guarded mutation (see atomicShard.gen) since a
+// reader could otherwise be probing through the middle of the chain while
+// it's relocated out from under it.
+func (m *atomicShard[K, V]) remove(st *atomicState[K, V], i uint64) {
+	m.gen.Add(1)
+	pi := i
+	for {
+		i = (i + 1) & st.mask
+		next := st.buckets[i].p.Load()
+		if next == nil || next.dib() <= 1 {
+			st.buckets[pi].p.Store(nil)
+			break
+		}
+		shifted := entry[K, V]{hdib: next.hdib, key: next.key, value: next.value}
+		shifted.setDIB(shifted.dib() - 1)
+		st.buckets[pi].p.Store(&shifted)
+		pi = i
+	}
+	m.length--
+	m.gen.Add(1)
+	if len(st.buckets) > m.cap && m.length <= m.shrinkAt {
+		m.resize(m.length)
+	}
+}
+
+// Scan iterates over all key/values.
+// It's not safe to call Set or Delete while scanning.
+func (m *atomicShard[K, V]) Scan(iter func(key K, value V) bool) {
+	st := m.state.Load()
+	for i := range st.buckets {
+		e := st.buckets[i].p.Load()
+		if e != nil {
+			if !iter(e.key, e.value) {
+				return
+			}
+		}
+	}
+}
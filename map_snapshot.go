@@ -0,0 +1,222 @@
+package shardmap
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/zeebo/xxh3"
+)
+
+var snapshotMagic = [4]byte{'S', 'M', 'A', 'P'}
+
+const snapshotVersion uint32 = 1
+
+// EncodeFn encodes a single value of type T to w.
+type EncodeFn[T any] func(w io.Writer, v T) error
+
+// DecodeFn decodes a single value of type T from r.
+type DecodeFn[T any] func(r io.Reader) (T, error)
+
+// WriteSnapshot serializes the map to w in a length-prefixed framed binary
+// format: a magic header and version, the shard count, then one chunk per
+// shard holding its entry count, its entries (each entry's precomputed
+// hash plus its length-prefixed encoded key and value), and an xxh3
+// checksum computed as a running digest over the chunk's bytes. Each shard
+// is read under only that shard's RLock, so a large map can be snapshotted
+// without stopping writers on the map's other shards.
+func (m *Map[K, V]) WriteSnapshot(w io.Writer, encKey EncodeFn[K], encVal EncodeFn[V]) error {
+	m.Init()
+
+	if _, err := w.Write(snapshotMagic[:]); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, snapshotVersion); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(m.shards)); err != nil {
+		return err
+	}
+
+	for i := 0; i < m.shards; i++ {
+		if err := m.writeShardSnapshot(w, i, encKey, encVal); err != nil {
+			return fmt.Errorf("shardmap: snapshot shard %d: %w", i, err)
+		}
+	}
+	return nil
+}
+
+func (m *Map[K, V]) writeShardSnapshot(w io.Writer, i int, encKey EncodeFn[K], encVal EncodeFn[V]) error {
+	m.mus[i].RLock()
+	defer m.mus[i].RUnlock()
+
+	if err := binary.Write(w, binary.LittleEndian, uint32(m.maps[i].Len())); err != nil {
+		return err
+	}
+
+	digest := xxh3.New()
+	dw := io.MultiWriter(w, digest)
+	var scanErr error
+	m.maps[i].Scan(func(key K, value V) bool {
+		hash := makeHash(m.hasher.Hash(key))
+		if scanErr = binary.Write(dw, binary.LittleEndian, hash); scanErr != nil {
+			return false
+		}
+		if scanErr = writeFramed(dw, encKey, key); scanErr != nil {
+			return false
+		}
+		if scanErr = writeFramed(dw, encVal, value); scanErr != nil {
+			return false
+		}
+		return true
+	})
+	if scanErr != nil {
+		return scanErr
+	}
+	return binary.Write(w, binary.LittleEndian, digest.Sum64())
+}
+
+// writeFramed buffers v's encoding so its length can be written ahead of it.
+func writeFramed[T any](w io.Writer, enc EncodeFn[T], v T) error {
+	var buf countingBuffer
+	if err := enc(&buf, v); err != nil {
+		return err
+	}
+	if err := binary.Write(w, binary.LittleEndian, uint32(len(buf.b))); err != nil {
+		return err
+	}
+	_, err := w.Write(buf.b)
+	return err
+}
+
+// countingBuffer is a minimal io.Writer sink used only to measure and hold
+// an entry's encoded bytes before framing them with a length prefix.
+type countingBuffer struct{ b []byte }
+
+func (c *countingBuffer) Write(p []byte) (int, error) {
+	c.b = append(c.b, p...)
+	return len(p), nil
+}
+
+// ReadSnapshot replaces the map's contents by deserializing r, which must
+// have been produced by WriteSnapshot. The snapshot's shard count need not
+// match this map's: it was produced by a GOMAXPROCS-derived shard count that
+// can differ across hosts/containers, so every decoded entry is re-bucketed
+// by this map's own shard mask (the persisted hash is discarded and
+// recomputed via the map's Hasher) rather than restored into the
+// like-numbered shard it came from. Entries from every source chunk are
+// gathered by destination shard before any shard is (re)built, so each
+// shard can still be preallocated to its final size instead of growing
+// incrementally as entries trickle in from unrelated source chunks.
+func (m *Map[K, V]) ReadSnapshot(r io.Reader, decKey DecodeFn[K], decVal DecodeFn[V]) error {
+	m.Init()
+
+	var magic [4]byte
+	if _, err := io.ReadFull(r, magic[:]); err != nil {
+		return fmt.Errorf("shardmap: read snapshot header: %w", err)
+	}
+	if magic != snapshotMagic {
+		return errors.New("shardmap: not a shardmap snapshot (bad magic)")
+	}
+	var version uint32
+	if err := binary.Read(r, binary.LittleEndian, &version); err != nil {
+		return err
+	}
+	if version != snapshotVersion {
+		return fmt.Errorf("shardmap: unsupported snapshot version %d", version)
+	}
+	var srcShards uint32
+	if err := binary.Read(r, binary.LittleEndian, &srcShards); err != nil {
+		return err
+	}
+
+	buckets := make([][]entryHash[K, V], m.shards)
+	for i := 0; i < int(srcShards); i++ {
+		if err := m.readShardSnapshot(r, decKey, decVal, buckets); err != nil {
+			return fmt.Errorf("shardmap: snapshot shard %d: %w", i, err)
+		}
+	}
+
+	for shard, entries := range buckets {
+		m.mus[shard].Lock()
+		m.maps[shard] = m.newShard(len(entries))
+		for _, e := range entries {
+			m.maps[shard].SetWithHash(e.hash, e.key, e.value)
+		}
+		m.mus[shard].Unlock()
+	}
+	return nil
+}
+
+// readShardSnapshot decodes one persisted shard's chunk of entries and
+// appends each one to buckets[shard], re-bucketing by this map's own shard
+// mask rather than trusting the chunk index, since the restoring map may
+// have a different shard count than the one that wrote the snapshot.
+// buckets accumulates across every call for a given ReadSnapshot so the
+// caller can preallocate each destination shard exactly once, after every
+// source chunk has been decoded.
+func (m *Map[K, V]) readShardSnapshot(r io.Reader, decKey DecodeFn[K], decVal DecodeFn[V], buckets [][]entryHash[K, V]) error {
+	var count uint32
+	if err := binary.Read(r, binary.LittleEndian, &count); err != nil {
+		return err
+	}
+
+	digest := xxh3.New()
+	dr := io.TeeReader(r, digest)
+
+	for n := uint32(0); n < count; n++ {
+		var srcHash uint64
+		if err := binary.Read(dr, binary.LittleEndian, &srcHash); err != nil {
+			return err
+		}
+		key, err := readFramed(dr, decKey)
+		if err != nil {
+			return err
+		}
+		val, err := readFramed(dr, decVal)
+		if err != nil {
+			return err
+		}
+		shard, hash := m.choose(key)
+		buckets[shard] = append(buckets[shard], entryHash[K, V]{key, val, hash})
+	}
+
+	var wantSum uint64
+	if err := binary.Read(r, binary.LittleEndian, &wantSum); err != nil {
+		return err
+	}
+	if gotSum := digest.Sum64(); gotSum != wantSum {
+		return fmt.Errorf("shardmap: checksum mismatch")
+	}
+	return nil
+}
+
+// readFramed reads a length-prefixed entry and decodes it, discarding any
+// bytes dec left unread so the stream stays aligned on the next frame.
+func readFramed[T any](r io.Reader, dec DecodeFn[T]) (T, error) {
+	var zero T
+	var length uint32
+	if err := binary.Read(r, binary.LittleEndian, &length); err != nil {
+		return zero, err
+	}
+	lr := io.LimitReader(r, int64(length))
+	v, err := dec(lr)
+	if err != nil {
+		return zero, err
+	}
+	if _, err := io.Copy(io.Discard, lr); err != nil {
+		return zero, err
+	}
+	return v, nil
+}
+
+// ImportMap bulk-inserts every entry of src, a compatibility path for data
+// gathered with plain Range rather than a WriteSnapshot-produced stream
+// (e.g. a map[K]V built by collecting the pairs Range yields).
+func (m *Map[K, V]) ImportMap(src map[K]V) {
+	m.Init()
+	for k, v := range src {
+		m.Set(k, v)
+	}
+}
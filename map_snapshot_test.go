@@ -0,0 +1,214 @@
+package shardmap
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/zeebo/xxh3"
+)
+
+func encodeInt(w io.Writer, v int) error {
+	return binary.Write(w, binary.LittleEndian, int64(v))
+}
+
+func decodeInt(r io.Reader) (int, error) {
+	var v int64
+	err := binary.Read(r, binary.LittleEndian, &v)
+	return int(v), err
+}
+
+// collect drains m into a plain map for comparison against another Map's
+// contents, since Map has no exported equality check of its own.
+func collect[K comparable, V any](m *Map[K, V]) map[K]V {
+	out := make(map[K]V)
+	m.Range(func(key K, value V) bool {
+		out[key] = value
+		return true
+	})
+	return out
+}
+
+func TestSnapshotRoundTrip(t *testing.T) {
+	const n = 50000
+	m := New[int, int](0)
+	for i := 0; i < n; i++ {
+		m.Set(i, i*i)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WriteSnapshot(&buf, encodeInt, encodeInt); err != nil {
+		t.Fatalf("WriteSnapshot: %v", err)
+	}
+
+	m2 := New[int, int](0)
+	if err := m2.ReadSnapshot(&buf, decodeInt, decodeInt); err != nil {
+		t.Fatalf("ReadSnapshot: %v", err)
+	}
+
+	want := collect(m)
+	got := collect(m2)
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("key %d: got %d, want %d", k, got[k], v)
+		}
+	}
+}
+
+func TestSnapshotRoundTripLockFree(t *testing.T) {
+	const n = 50000
+	m := NewWithOptions[int, int](0, Options{LockFreeReads: true})
+	for i := 0; i < n; i++ {
+		m.Set(i, i*i)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WriteSnapshot(&buf, encodeInt, encodeInt); err != nil {
+		t.Fatalf("WriteSnapshot: %v", err)
+	}
+
+	m2 := NewWithOptions[int, int](0, Options{LockFreeReads: true})
+	if err := m2.ReadSnapshot(&buf, decodeInt, decodeInt); err != nil {
+		t.Fatalf("ReadSnapshot: %v", err)
+	}
+
+	want := collect(m)
+	got := collect(m2)
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("key %d: got %d, want %d", k, got[k], v)
+		}
+	}
+}
+
+// TestSnapshotRestoreDifferentShardCount hand-builds a snapshot stream as if
+// it were written by a Map with a different (smaller) shard count than the
+// one restoring it, the way a snapshot taken on one host/container and
+// restored on another with a different GOMAXPROCS would look. ReadSnapshot
+// must re-bucket every entry by the restoring map's own shard mask instead
+// of rejecting the shard-count mismatch.
+func TestSnapshotRestoreDifferentShardCount(t *testing.T) {
+	const n = 5000
+	const srcShards = 4
+
+	var buf bytes.Buffer
+	buf.Write(snapshotMagic[:])
+	binary.Write(&buf, binary.LittleEndian, snapshotVersion)
+	binary.Write(&buf, binary.LittleEndian, uint32(srcShards))
+
+	want := make(map[int]int, n)
+	for i := 0; i < n; i++ {
+		want[i] = i * i
+	}
+
+	// Put every entry in the first chunk; the remaining srcShards-1 chunks
+	// are empty. ReadSnapshot shouldn't care how the source distributed
+	// entries across its chunks.
+	for shard := 0; shard < srcShards; shard++ {
+		var chunk bytes.Buffer
+		count := 0
+		if shard == 0 {
+			count = n
+		}
+		binary.Write(&chunk, binary.LittleEndian, uint32(count))
+		digest := xxh3.New()
+		dw := io.MultiWriter(&chunk, digest)
+		if shard == 0 {
+			for i := 0; i < n; i++ {
+				binary.Write(dw, binary.LittleEndian, uint64(i)) // arbitrary stale hash, must be ignored
+				writeFramed(dw, encodeInt, i)
+				writeFramed(dw, encodeInt, i*i)
+			}
+		}
+		binary.Write(&chunk, binary.LittleEndian, digest.Sum64())
+		buf.Write(chunk.Bytes())
+	}
+
+	m := New[int, int](0)
+	if err := m.ReadSnapshot(&buf, decodeInt, decodeInt); err != nil {
+		t.Fatalf("ReadSnapshot: %v", err)
+	}
+	got := collect(m)
+	if len(got) != len(want) {
+		t.Fatalf("got %d entries, want %d", len(got), len(want))
+	}
+	for k, v := range want {
+		if got[k] != v {
+			t.Fatalf("key %d: got %d, want %d", k, got[k], v)
+		}
+	}
+}
+
+// TestSnapshotConcurrentWriters exercises WriteSnapshot's documented claim
+// that it can run against a map with writers still active on other shards:
+// it starts writers hammering Set/Delete across the whole key space, takes a
+// snapshot mid-flight, and checks that every entry the snapshot did capture
+// decodes back to the value it actually held (i.e. no torn/corrupt reads),
+// not that it captures any particular subset of the concurrent writes.
+func TestSnapshotConcurrentWriters(t *testing.T) {
+	const n = 20000
+	m := New[int, int](0)
+	for i := 0; i < n; i++ {
+		m.Set(i, i)
+	}
+
+	stop := make(chan struct{})
+	var wg sync.WaitGroup
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for i := 0; ; i++ {
+				select {
+				case <-stop:
+					return
+				default:
+				}
+				key := (i*7 + w) % n
+				if i%2 == 0 {
+					m.Set(key, key+w)
+				} else {
+					m.Delete(key)
+				}
+			}
+		}(w)
+	}
+
+	var buf bytes.Buffer
+	if err := m.WriteSnapshot(&buf, encodeInt, encodeInt); err != nil {
+		close(stop)
+		wg.Wait()
+		t.Fatalf("WriteSnapshot: %v", err)
+	}
+	close(stop)
+	wg.Wait()
+
+	m2 := New[int, int](0)
+	if err := m2.ReadSnapshot(&buf, decodeInt, decodeInt); err != nil {
+		t.Fatalf("ReadSnapshot: %v", err)
+	}
+
+	// Every restored entry must be a value that was legitimately assigned to
+	// its key (key, key+w for some w in [0,4), or the initial key), never a
+	// torn mix of bytes from two different writes.
+	m2.Range(func(key, value int) bool {
+		if value == key {
+			return true
+		}
+		for w := 0; w < 4; w++ {
+			if value == key+w {
+				return true
+			}
+		}
+		t.Fatalf("key %d: value %d was never a value Set assigned it", key, value)
+		return false
+	})
+}